@@ -0,0 +1,180 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"istio.io/istio/pkg/test/framework/component"
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// ResolutionReport describes what Tracker.Initialize would do for a given
+// component without actually calling Init on anything. It lets CI compute
+// the minimum set of environment components a test needs, and lets tests
+// validate registry.Registry completeness without paying for side-effectful
+// initialization.
+type ResolutionReport struct {
+	// Waves mirrors InitPlan.Waves, minus the Reused IDs and any wave left
+	// empty by their removal: successive groups of components that would be
+	// initialized concurrently.
+	Waves [][]dependency.Instance `json:"waves"`
+	// Reused lists the IDs that are already present in the Tracker's
+	// instanceMap and would be reused rather than re-initialized.
+	Reused []dependency.Instance `json:"reused,omitempty"`
+	// Unresolved lists dependency IDs referenced by Requires() that could
+	// not be found in the registry.
+	Unresolved []dependency.Instance `json:"unresolved,omitempty"`
+	// Cycles lists any circular dependencies detected while planning,
+	// using the same format as CycleError.Cycles.
+	Cycles [][]dependency.Instance `json:"cycles,omitempty"`
+
+	// requires records, for each component encountered, what it depends
+	// on. It is used to render String() as a tree.
+	requires map[dependency.Instance][]dependency.Instance
+}
+
+// DryRun walks c.Requires() transitively, without calling c.Init, and
+// returns a ResolutionReport describing the plan that Initialize would
+// execute. Already-initialized components are reported as Reused instead of
+// appearing in a wave.
+func (t *Tracker) DryRun(ctx context.Context, c component.Component) (*ResolutionReport, error) {
+	report := &ResolutionReport{
+		requires: make(map[dependency.Instance][]dependency.Instance),
+	}
+
+	seen := make(map[dependency.Instance]bool)
+	var walk func(comp component.Component)
+	walk = func(comp component.Component) {
+		id := comp.ID()
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+
+		t.mu.Lock()
+		_, reused := t.instanceMap[id]
+		t.mu.Unlock()
+		if reused {
+			report.Reused = append(report.Reused, id)
+		}
+
+		for _, depID := range comp.Requires() {
+			report.requires[id] = append(report.requires[id], depID)
+
+			depComp, ok := t.registry.Get(depID)
+			if !ok {
+				report.Unresolved = append(report.Unresolved, depID)
+				continue
+			}
+			walk(depComp)
+		}
+	}
+	walk(c)
+
+	plan, err := Plan(ctx, t.registry, []component.Component{c})
+	switch cycleErr := (*CycleError)(nil); {
+	case err == nil:
+		report.Waves = withoutReused(plan.Waves, report.Reused)
+	case errors.As(err, &cycleErr):
+		report.Cycles = cycleErr.Cycles
+	case len(report.Unresolved) > 0:
+		// Plan failed for the same missing-dependency reason the walk above
+		// already recorded in report.Unresolved; there is nothing more to
+		// add, and the whole point of DryRun is to surface that without
+		// returning an error.
+	default:
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// withoutReused returns waves with every reused ID removed, along with any
+// wave that ends up empty as a result. Waves only describes work Initialize
+// would still have to do, and a reused component needs no further Init
+// call.
+func withoutReused(waves [][]dependency.Instance, reusedIDs []dependency.Instance) [][]dependency.Instance {
+	if len(reusedIDs) == 0 {
+		return waves
+	}
+
+	reused := make(map[dependency.Instance]bool, len(reusedIDs))
+	for _, id := range reusedIDs {
+		reused[id] = true
+	}
+
+	var filtered [][]dependency.Instance
+	for _, wave := range waves {
+		var remaining []dependency.Instance
+		for _, id := range wave {
+			if !reused[id] {
+				remaining = append(remaining, id)
+			}
+		}
+		if len(remaining) > 0 {
+			filtered = append(filtered, remaining)
+		}
+	}
+	return filtered
+}
+
+// String renders the report as an indented tree, suitable for `-v` test
+// output: each wave is a level of the tree of remaining Init work, and
+// components that would be reused from a prior run are called out in their
+// own section instead.
+func (r *ResolutionReport) String() string {
+	var b strings.Builder
+
+	for i, wave := range r.Waves {
+		fmt.Fprintf(&b, "wave %d:\n", i)
+		for _, id := range wave {
+			fmt.Fprintf(&b, "  %s\n", id)
+			for _, depID := range r.requires[id] {
+				fmt.Fprintf(&b, "    requires %s\n", depID)
+			}
+		}
+	}
+
+	if len(r.Reused) > 0 {
+		fmt.Fprintf(&b, "reused:\n")
+		for _, id := range r.Reused {
+			fmt.Fprintf(&b, "  %s\n", id)
+		}
+	}
+
+	if len(r.Unresolved) > 0 {
+		fmt.Fprintf(&b, "unresolved:\n")
+		for _, id := range r.Unresolved {
+			fmt.Fprintf(&b, "  %s\n", id)
+		}
+	}
+
+	if len(r.Cycles) > 0 {
+		fmt.Fprintf(&b, "cycles:\n")
+		for _, cycle := range r.Cycles {
+			ids := make([]string, 0, len(cycle))
+			for _, id := range cycle {
+				ids = append(ids, string(id))
+			}
+			fmt.Fprintf(&b, "  %s\n", strings.Join(ids, " -> "))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+func TestTracker_DryRun_ReusedExcludedFromWaves(t *testing.T) {
+	d := &fakeComponent{id: "d"}
+	b := &fakeComponent{id: "b", requires: []dependency.Instance{"d"}}
+	tracker := newTracker(newFakeRegistry(b, d))
+
+	if _, err := tracker.Initialize(nil, d); err != nil {
+		t.Fatalf("Initialize() returned unexpected error: %v", err)
+	}
+
+	report, err := tracker.DryRun(context.Background(), b)
+	if err != nil {
+		t.Fatalf("DryRun() returned unexpected error: %v", err)
+	}
+
+	if len(report.Reused) != 1 || report.Reused[0] != "d" {
+		t.Fatalf("expected d to be reused, got %v", report.Reused)
+	}
+
+	foundB := false
+	for _, wave := range report.Waves {
+		for _, id := range wave {
+			if id == "d" {
+				t.Fatalf("reused component d must not appear in Waves: %v", report.Waves)
+			}
+			if id == "b" {
+				foundB = true
+			}
+		}
+	}
+	if !foundB {
+		t.Fatalf("expected b to still appear in Waves, got %v", report.Waves)
+	}
+}
+
+func TestTracker_DryRun_Unresolved(t *testing.T) {
+	b := &fakeComponent{id: "b", requires: []dependency.Instance{"missing"}}
+	tracker := newTracker(newFakeRegistry(b))
+
+	report, err := tracker.DryRun(context.Background(), b)
+	if err != nil {
+		t.Fatalf("DryRun() returned unexpected error: %v", err)
+	}
+	if len(report.Unresolved) != 1 || report.Unresolved[0] != "missing" {
+		t.Fatalf("expected missing to be unresolved, got %v", report.Unresolved)
+	}
+}
+
+func TestTracker_DryRun_Cycle(t *testing.T) {
+	a := &fakeComponent{id: "a", requires: []dependency.Instance{"b"}}
+	b := &fakeComponent{id: "b", requires: []dependency.Instance{"a"}}
+	tracker := newTracker(newFakeRegistry(a, b))
+
+	report, err := tracker.DryRun(context.Background(), a)
+	if err != nil {
+		t.Fatalf("DryRun() returned unexpected error: %v", err)
+	}
+	if len(report.Cycles) != 1 || len(report.Cycles[0]) != 2 {
+		t.Fatalf("expected a single 2-node cycle, got %v", report.Cycles)
+	}
+}
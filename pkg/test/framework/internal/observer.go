@@ -0,0 +1,82 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"time"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// LifecycleObserver can be registered with a Tracker via AddObserver to be
+// notified of component lifecycle transitions, without requiring any change
+// to the Tracker itself. Implementations must be safe for concurrent use,
+// since Initialize may invoke OnInitStart/OnInitEnd for multiple components
+// in the same wave concurrently.
+type LifecycleObserver interface {
+	// OnInitStart is called immediately before a component's Init is invoked.
+	OnInitStart(id dependency.Instance)
+	// OnInitEnd is called after a component's Init returns, whether it
+	// succeeded or failed.
+	OnInitEnd(id dependency.Instance, err error, dur time.Duration)
+	// OnResetEnd is called after a component's Reset returns.
+	OnResetEnd(id dependency.Instance, err error)
+	// OnCleanupEnd is called after a component's Close returns.
+	OnCleanupEnd(id dependency.Instance, err error)
+}
+
+// AddObserver registers o to receive future lifecycle events for components
+// tracked by t. Observers are notified in registration order.
+func (t *Tracker) AddObserver(o LifecycleObserver) {
+	t.observersMu.Lock()
+	defer t.observersMu.Unlock()
+	t.observers = append(t.observers, o)
+}
+
+func (t *Tracker) notifyInitStart(id dependency.Instance) {
+	t.observersMu.Lock()
+	observers := t.observers
+	t.observersMu.Unlock()
+	for _, o := range observers {
+		o.OnInitStart(id)
+	}
+}
+
+func (t *Tracker) notifyInitEnd(id dependency.Instance, err error, dur time.Duration) {
+	t.observersMu.Lock()
+	observers := t.observers
+	t.observersMu.Unlock()
+	for _, o := range observers {
+		o.OnInitEnd(id, err, dur)
+	}
+}
+
+func (t *Tracker) notifyResetEnd(id dependency.Instance, err error) {
+	t.observersMu.Lock()
+	observers := t.observers
+	t.observersMu.Unlock()
+	for _, o := range observers {
+		o.OnResetEnd(id, err)
+	}
+}
+
+func (t *Tracker) notifyCleanupEnd(id dependency.Instance, err error) {
+	t.observersMu.Lock()
+	observers := t.observers
+	t.observersMu.Unlock()
+	for _, o := range observers {
+		o.OnCleanupEnd(id, err)
+	}
+}
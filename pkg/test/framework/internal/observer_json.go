@@ -0,0 +1,90 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// traceEvent is a single entry written by JSONTraceObserver.
+type traceEvent struct {
+	Time      time.Time           `json:"time"`
+	Component dependency.Instance `json:"component"`
+	Event     string              `json:"event"`
+	DurationS float64             `json:"durationSeconds,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// JSONTraceObserver is a LifecycleObserver that writes one JSON object per
+// line to w for every lifecycle event it observes, for post-run analysis of
+// which components dominate test startup time.
+type JSONTraceObserver struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONTraceObserver creates a JSONTraceObserver that writes events to w.
+func NewJSONTraceObserver(w io.Writer) *JSONTraceObserver {
+	return &JSONTraceObserver{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (o *JSONTraceObserver) write(e traceEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// Best-effort: a failure to write a trace event must not fail the test
+	// run, so the error is intentionally discarded here.
+	_ = o.enc.Encode(e)
+}
+
+// OnInitStart implements LifecycleObserver.
+func (o *JSONTraceObserver) OnInitStart(id dependency.Instance) {
+	o.write(traceEvent{Time: time.Now(), Component: id, Event: "init_start"})
+}
+
+// OnInitEnd implements LifecycleObserver.
+func (o *JSONTraceObserver) OnInitEnd(id dependency.Instance, err error, dur time.Duration) {
+	e := traceEvent{Time: time.Now(), Component: id, Event: "init_end", DurationS: dur.Seconds()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	o.write(e)
+}
+
+// OnResetEnd implements LifecycleObserver.
+func (o *JSONTraceObserver) OnResetEnd(id dependency.Instance, err error) {
+	e := traceEvent{Time: time.Now(), Component: id, Event: "reset_end"}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	o.write(e)
+}
+
+// OnCleanupEnd implements LifecycleObserver.
+func (o *JSONTraceObserver) OnCleanupEnd(id dependency.Instance, err error) {
+	e := traceEvent{Time: time.Now(), Component: id, Event: "cleanup_end"}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	o.write(e)
+}
@@ -0,0 +1,77 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// PrometheusObserver is a LifecycleObserver that records how long each
+// component takes to initialize, and how often initialization fails, as
+// Prometheus metrics labeled by component ID. Register its Collectors() with
+// a prometheus.Registerer before wiring it up via Tracker.AddObserver so the
+// metrics get scraped alongside the rest of the test run.
+type PrometheusObserver struct {
+	initDuration *prometheus.HistogramVec
+	initFailures *prometheus.CounterVec
+}
+
+// NewPrometheusObserver creates a PrometheusObserver whose metrics are
+// named under the given namespace. It does not register them with any
+// prometheus.Registerer.
+func NewPrometheusObserver(namespace string) *PrometheusObserver {
+	return &PrometheusObserver{
+		initDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "component",
+			Name:      "init_duration_seconds",
+			Help:      "Time taken to initialize a test framework component.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"component"}),
+		initFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "component",
+			Name:      "init_failures_total",
+			Help:      "Number of test framework component initialization failures.",
+		}, []string{"component"}),
+	}
+}
+
+// Collectors returns the Prometheus collectors owned by this observer, for
+// registration with a prometheus.Registerer.
+func (o *PrometheusObserver) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{o.initDuration, o.initFailures}
+}
+
+// OnInitStart implements LifecycleObserver.
+func (o *PrometheusObserver) OnInitStart(id dependency.Instance) {}
+
+// OnInitEnd implements LifecycleObserver.
+func (o *PrometheusObserver) OnInitEnd(id dependency.Instance, err error, dur time.Duration) {
+	o.initDuration.WithLabelValues(string(id)).Observe(dur.Seconds())
+	if err != nil {
+		o.initFailures.WithLabelValues(string(id)).Inc()
+	}
+}
+
+// OnResetEnd implements LifecycleObserver.
+func (o *PrometheusObserver) OnResetEnd(id dependency.Instance, err error) {}
+
+// OnCleanupEnd implements LifecycleObserver.
+func (o *PrometheusObserver) OnCleanupEnd(id dependency.Instance, err error) {}
@@ -0,0 +1,119 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+	"istio.io/istio/pkg/test/framework/environment"
+)
+
+// fakeFailingComponent always fails Init, for exercising observer
+// notification on a failed initialization.
+type fakeFailingComponent struct {
+	id  dependency.Instance
+	err error
+}
+
+func (f *fakeFailingComponent) ID() dependency.Instance { return f.id }
+
+func (f *fakeFailingComponent) Requires() []dependency.Instance { return nil }
+
+func (f *fakeFailingComponent) Init(environment.ComponentContext, map[dependency.Instance]interface{}) (interface{}, error) {
+	return nil, f.err
+}
+
+// recordingObserver is a LifecycleObserver that appends a string per event
+// to a shared log, tagged with name, so tests can assert both on per-event
+// content and on fan-out order across multiple observers.
+type recordingObserver struct {
+	name string
+	log  *eventLog
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) record(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, s)
+}
+
+func (o *recordingObserver) OnInitStart(id dependency.Instance) {
+	o.log.record(o.name + ":initStart:" + string(id))
+}
+
+func (o *recordingObserver) OnInitEnd(id dependency.Instance, err error, _ time.Duration) {
+	status := "ok"
+	if err != nil {
+		status = "err"
+	}
+	o.log.record(o.name + ":initEnd:" + string(id) + ":" + status)
+}
+
+func (o *recordingObserver) OnResetEnd(id dependency.Instance, _ error) {
+	o.log.record(o.name + ":resetEnd:" + string(id))
+}
+
+func (o *recordingObserver) OnCleanupEnd(id dependency.Instance, _ error) {
+	o.log.record(o.name + ":cleanupEnd:" + string(id))
+}
+
+func TestTracker_ObserverFanOut(t *testing.T) {
+	c := &fakeComponent{id: "a"}
+	tracker := newTracker(newFakeRegistry(c))
+
+	log := &eventLog{}
+	tracker.AddObserver(&recordingObserver{name: "first", log: log})
+	tracker.AddObserver(&recordingObserver{name: "second", log: log})
+
+	if _, err := tracker.Initialize(nil, c); err != nil {
+		t.Fatalf("Initialize() returned unexpected error: %v", err)
+	}
+
+	want := []string{
+		"first:initStart:a", "second:initStart:a",
+		"first:initEnd:a:ok", "second:initEnd:a:ok",
+	}
+	if !reflect.DeepEqual(log.events, want) {
+		t.Fatalf("observer events = %v, want %v", log.events, want)
+	}
+}
+
+func TestTracker_ObserverFanOut_InitFailure(t *testing.T) {
+	failure := errors.New("init failed")
+	c := &fakeFailingComponent{id: "a", err: failure}
+	tracker := newTracker(newFakeRegistry(c))
+
+	log := &eventLog{}
+	tracker.AddObserver(&recordingObserver{name: "only", log: log})
+
+	if _, err := tracker.Initialize(nil, c); !errors.Is(err, failure) {
+		t.Fatalf("Initialize() error = %v, want %v", err, failure)
+	}
+
+	want := []string{"only:initStart:a", "only:initEnd:a:err"}
+	if !reflect.DeepEqual(log.events, want) {
+		t.Fatalf("observer events = %v, want %v", log.events, want)
+	}
+}
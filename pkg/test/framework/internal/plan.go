@@ -0,0 +1,244 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/istio/pkg/test/framework/component"
+	"istio.io/istio/pkg/test/framework/components/registry"
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// InitPlan describes a topologically-ordered, wave-based plan for
+// initializing a set of components and their transitive dependencies.
+// Components within the same wave have no dependency relationship between
+// them, so Tracker.Initialize is free to initialize an entire wave
+// concurrently. A wave is only started once every component in every prior
+// wave has finished initializing.
+type InitPlan struct {
+	// Waves holds successive groups of component IDs that can be
+	// initialized concurrently.
+	Waves [][]dependency.Instance
+
+	// nodes maps every component ID reachable from the plan's roots to the
+	// component.Component that produced it.
+	nodes map[dependency.Instance]component.Component
+}
+
+// CycleError is returned by Plan when the dependency graph rooted at the
+// requested components contains one or more circular dependencies. Cycles
+// holds one entry per strongly-connected component discovered in the
+// residual graph, each listing its member IDs in Tarjan traversal order.
+type CycleError struct {
+	Cycles [][]dependency.Instance
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, 0, len(e.Cycles))
+	for _, cycle := range e.Cycles {
+		ids := make([]string, 0, len(cycle))
+		for _, id := range cycle {
+			ids = append(ids, string(id))
+		}
+		parts = append(parts, "["+strings.Join(ids, " -> ")+"]")
+	}
+	return fmt.Sprintf("circular dependency detected among components: %s", strings.Join(parts, ", "))
+}
+
+// Plan computes a wave-based initialization plan for roots and all of their
+// transitive dependencies, resolved via reg. It implements Kahn's algorithm
+// over the dependency.Instance graph: components with no unsatisfied
+// dependencies form the first wave, and subsequent waves are formed as their
+// dependents become eligible.
+//
+// If Kahn's algorithm terminates with nodes still unresolved, the dependency
+// graph contains a cycle. In that case Plan runs Tarjan's strongly-connected
+// components algorithm over the residual graph and returns a *CycleError
+// describing it.
+func Plan(ctx context.Context, reg *registry.Registry, roots []component.Component) (*InitPlan, error) {
+	nodes := make(map[dependency.Instance]component.Component)
+	// dependents[id] holds the components that require id, i.e. the edges
+	// that become unblocked once id has been initialized.
+	dependents := make(map[dependency.Instance][]dependency.Instance)
+	inDegree := make(map[dependency.Instance]int)
+
+	var collect func(c component.Component) error
+	collect = func(c component.Component) error {
+		id := c.ID()
+		if _, ok := nodes[id]; ok {
+			return nil
+		}
+		nodes[id] = c
+		if _, ok := inDegree[id]; !ok {
+			inDegree[id] = 0
+		}
+
+		for _, depID := range c.Requires() {
+			depComp, ok := reg.Get(depID)
+			if !ok {
+				return fmt.Errorf("unable to resolve dependency %s for component %s", depID, id)
+			}
+			dependents[depID] = append(dependents[depID], id)
+			inDegree[id]++
+
+			if err := collect(depComp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := collect(root); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining := make(map[dependency.Instance]int, len(inDegree))
+	for id, deg := range inDegree {
+		remaining[id] = deg
+	}
+
+	var ready []dependency.Instance
+	for id, deg := range remaining {
+		if deg == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	plan := &InitPlan{nodes: nodes}
+	for len(ready) > 0 {
+		// Sort so that plan generation is deterministic; the components in
+		// a wave still have no ordering dependency on each other.
+		sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+		wave := ready
+		plan.Waves = append(plan.Waves, wave)
+
+		for _, id := range wave {
+			delete(remaining, id)
+		}
+
+		var next []dependency.Instance
+		for _, id := range wave {
+			for _, depID := range dependents[id] {
+				if _, ok := remaining[depID]; !ok {
+					continue
+				}
+				remaining[depID]--
+				if remaining[depID] == 0 {
+					next = append(next, depID)
+				}
+			}
+		}
+		ready = next
+	}
+
+	if len(remaining) > 0 {
+		return nil, &CycleError{Cycles: findCycles(remaining, dependents)}
+	}
+
+	return plan, nil
+}
+
+// findCycles runs Tarjan's strongly-connected-components algorithm over the
+// nodes that Kahn's algorithm in Plan was unable to retire, returning every
+// SCC of size greater than one, plus every single-node SCC whose node
+// requires itself, in discovery order.
+func findCycles(remaining map[dependency.Instance]int, dependents map[dependency.Instance][]dependency.Instance) [][]dependency.Instance {
+	type tarjanState struct {
+		index   int
+		lowlink int
+		onStack bool
+	}
+
+	ids := make([]dependency.Instance, 0, len(remaining))
+	for id := range remaining {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	state := make(map[dependency.Instance]*tarjanState)
+	var stack []dependency.Instance
+	var sccs [][]dependency.Instance
+	index := 0
+
+	var strongconnect func(v dependency.Instance)
+	strongconnect = func(v dependency.Instance) {
+		state[v] = &tarjanState{index: index, lowlink: index, onStack: true}
+		index++
+		stack = append(stack, v)
+
+		for _, w := range dependents[v] {
+			if _, ok := remaining[w]; !ok {
+				// w was already retired before the cycle formed; it cannot
+				// be part of it.
+				continue
+			}
+			if ws, visited := state[w]; !visited {
+				strongconnect(w)
+				if state[w].lowlink < state[v].lowlink {
+					state[v].lowlink = state[w].lowlink
+				}
+			} else if ws.onStack && ws.index < state[v].lowlink {
+				state[v].lowlink = ws.index
+			}
+		}
+
+		if state[v].lowlink == state[v].index {
+			var scc []dependency.Instance
+			for {
+				n := len(stack) - 1
+				w := stack[n]
+				stack = stack[:n]
+				state[w].onStack = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			// A multi-node SCC is always a cycle. A single-node SCC is only
+			// a cycle if that node has a self-edge, i.e. a component that
+			// directly requires itself.
+			if len(scc) > 1 || isSelfLoop(scc[0], dependents) {
+				sccs = append(sccs, scc)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if _, visited := state[id]; !visited {
+			strongconnect(id)
+		}
+	}
+
+	return sccs
+}
+
+// isSelfLoop reports whether v requires itself, i.e. dependents[v] contains
+// an edge back to v. Tarjan's algorithm reports such a node as its own
+// singleton SCC, which findCycles would otherwise filter out along with
+// every other non-cyclic singleton.
+func isSelfLoop(v dependency.Instance, dependents map[dependency.Instance][]dependency.Instance) bool {
+	for _, w := range dependents[v] {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
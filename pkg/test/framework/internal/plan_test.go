@@ -0,0 +1,143 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"istio.io/istio/pkg/test/framework/component"
+	"istio.io/istio/pkg/test/framework/components/registry"
+	"istio.io/istio/pkg/test/framework/dependency"
+	"istio.io/istio/pkg/test/framework/environment"
+)
+
+// fakeComponent is a component.Component whose dependencies are fixed at
+// construction time, for exercising Plan without any real component.
+type fakeComponent struct {
+	id       dependency.Instance
+	requires []dependency.Instance
+}
+
+func (f *fakeComponent) ID() dependency.Instance { return f.id }
+
+func (f *fakeComponent) Requires() []dependency.Instance { return f.requires }
+
+func (f *fakeComponent) Init(_ environment.ComponentContext, _ map[dependency.Instance]interface{}) (interface{}, error) {
+	return f.id, nil
+}
+
+// newFakeRegistry builds a registry.Registry populated with components,
+// keyed by their own ID() so tests don't need to reference a concrete
+// fake's unexported fields.
+func newFakeRegistry(components ...component.Component) *registry.Registry {
+	reg := registry.New()
+	for _, c := range components {
+		reg.Register(c.ID(), c)
+	}
+	return reg
+}
+
+func waveIDs(waves [][]dependency.Instance) [][]dependency.Instance {
+	return waves
+}
+
+func containsWave(t *testing.T, waves [][]dependency.Instance, want []dependency.Instance) {
+	t.Helper()
+	for _, wave := range waves {
+		if len(wave) != len(want) {
+			continue
+		}
+		seen := make(map[dependency.Instance]bool, len(wave))
+		for _, id := range wave {
+			seen[id] = true
+		}
+		match := true
+		for _, id := range want {
+			if !seen[id] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return
+		}
+	}
+	t.Errorf("wave %v not found in plan waves %v", want, waveIDs(waves))
+}
+
+func TestPlan_SimpleDAG(t *testing.T) {
+	// a -> b -> d
+	// a -> c -> d
+	d := &fakeComponent{id: "d"}
+	b := &fakeComponent{id: "b", requires: []dependency.Instance{"d"}}
+	c := &fakeComponent{id: "c", requires: []dependency.Instance{"d"}}
+	a := &fakeComponent{id: "a", requires: []dependency.Instance{"b", "c"}}
+	reg := newFakeRegistry(a, b, c, d)
+
+	plan, err := Plan(context.Background(), reg, []component.Component{a})
+	if err != nil {
+		t.Fatalf("Plan() returned unexpected error: %v", err)
+	}
+
+	if len(plan.Waves) != 3 {
+		t.Fatalf("expected 3 waves, got %d: %v", len(plan.Waves), plan.Waves)
+	}
+	containsWave(t, plan.Waves, []dependency.Instance{"d"})
+	containsWave(t, plan.Waves, []dependency.Instance{"b", "c"})
+	containsWave(t, plan.Waves, []dependency.Instance{"a"})
+}
+
+func TestPlan_CycleDetection(t *testing.T) {
+	// a -> b -> a
+	a := &fakeComponent{id: "a", requires: []dependency.Instance{"b"}}
+	b := &fakeComponent{id: "b", requires: []dependency.Instance{"a"}}
+	reg := newFakeRegistry(a, b)
+
+	_, err := Plan(context.Background(), reg, []component.Component{a})
+	if err == nil {
+		t.Fatal("expected Plan() to detect a cycle, got nil error")
+	}
+
+	cycleErr, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("expected *CycleError, got %T: %v", err, err)
+	}
+	if len(cycleErr.Cycles) != 1 || len(cycleErr.Cycles[0]) != 2 {
+		t.Fatalf("expected a single 2-node cycle, got %v", cycleErr.Cycles)
+	}
+}
+
+func TestPlan_SelfLoopCycleDetection(t *testing.T) {
+	// a -> a
+	a := &fakeComponent{id: "a", requires: []dependency.Instance{"a"}}
+	reg := newFakeRegistry(a)
+
+	_, err := Plan(context.Background(), reg, []component.Component{a})
+	if err == nil {
+		t.Fatal("expected Plan() to detect a self-loop cycle, got nil error")
+	}
+
+	cycleErr, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("expected *CycleError, got %T: %v", err, err)
+	}
+	if len(cycleErr.Cycles) != 1 || len(cycleErr.Cycles[0]) != 1 || cycleErr.Cycles[0][0] != "a" {
+		t.Fatalf("expected a single 1-node cycle [a], got %v", cycleErr.Cycles)
+	}
+	if cycleErr.Error() == "circular dependency detected among components: " {
+		t.Fatalf("CycleError.Error() rendered no cycle members: %q", cycleErr.Error())
+	}
+}
@@ -0,0 +1,99 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+	"istio.io/istio/pkg/test/framework/environment"
+)
+
+// fakeSnapshotComponent is a component.Component and Snapshotter whose
+// "state" is a plain int, for exercising Tracker.Snapshot/Restore without a
+// real component.
+type fakeSnapshotComponent struct {
+	id    dependency.Instance
+	state int
+}
+
+func (f *fakeSnapshotComponent) ID() dependency.Instance { return f.id }
+
+func (f *fakeSnapshotComponent) Requires() []dependency.Instance { return nil }
+
+func (f *fakeSnapshotComponent) Init(environment.ComponentContext, map[dependency.Instance]interface{}) (interface{}, error) {
+	return f, nil
+}
+
+func (f *fakeSnapshotComponent) Snapshot() (SnapshotHandle, error) {
+	return f.state, nil
+}
+
+func (f *fakeSnapshotComponent) Restore(h SnapshotHandle) error {
+	f.state = h.(int)
+	return nil
+}
+
+func TestTracker_SnapshotRestore_LIFO(t *testing.T) {
+	c := &fakeSnapshotComponent{id: "s"}
+	tracker := newTracker(newFakeRegistry(c))
+
+	if _, err := tracker.Initialize(nil, c); err != nil {
+		t.Fatalf("Initialize() returned unexpected error: %v", err)
+	}
+
+	c.state = 1
+	if err := tracker.Snapshot("outer"); err != nil {
+		t.Fatalf("Snapshot(outer) returned unexpected error: %v", err)
+	}
+	c.state = 2
+	if err := tracker.Snapshot("inner"); err != nil {
+		t.Fatalf("Snapshot(inner) returned unexpected error: %v", err)
+	}
+	c.state = 3
+
+	if err := tracker.Restore("inner"); err != nil {
+		t.Fatalf("Restore(inner) returned unexpected error: %v", err)
+	}
+	if c.state != 2 {
+		t.Fatalf("state after Restore(inner) = %d, want 2", c.state)
+	}
+
+	if err := tracker.Restore("outer"); err != nil {
+		t.Fatalf("Restore(outer) returned unexpected error: %v", err)
+	}
+	if c.state != 1 {
+		t.Fatalf("state after Restore(outer) = %d, want 1", c.state)
+	}
+
+	// inner was already popped by the first Restore, so restoring it again
+	// must fail with a typed error rather than silently no-op.
+	err := tracker.Restore("inner")
+	var notFound *SnapshotNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "inner" {
+		t.Fatalf("Restore(inner) after it was popped = %v, want *SnapshotNotFoundError{Name: \"inner\"}", err)
+	}
+}
+
+func TestTracker_Restore_MissingName(t *testing.T) {
+	tracker := newTracker(newFakeRegistry())
+
+	err := tracker.Restore("never-taken")
+	var notFound *SnapshotNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "never-taken" {
+		t.Fatalf("Restore(never-taken) = %v, want *SnapshotNotFoundError{Name: \"never-taken\"}", err)
+	}
+}
@@ -15,9 +15,13 @@
 package internal
 
 import (
+	"context"
 	"io"
+	"sync"
+	"time"
 
 	"go.uber.org/multierr"
+	"golang.org/x/sync/errgroup"
 
 	"istio.io/istio/pkg/test/framework/scopes"
 
@@ -34,13 +38,37 @@ type componentInstance struct {
 	value interface{}
 }
 
+// initResult tracks a single in-flight call to component.Component.Init, so
+// that concurrent callers racing to initialize the same dependency ID can
+// claim it exactly once and the rest can wait for that claim's outcome
+// instead of calling Init again themselves.
+type initResult struct {
+	done chan struct{}
+	err  error
+}
+
 // Tracker keeps track of the state information for dependencies
 type Tracker struct {
+	// mu guards instanceMap and instances, which are read and written
+	// concurrently by Initialize's waves as well as by any other top-level
+	// Tracker call (Get, All, Reset, Cleanup, Snapshot, Restore, DryRun)
+	// that might race with it.
+	mu sync.Mutex
 	// Map dependency ID to instance
 	instanceMap map[dependency.Instance]interface{}
 	// Also store the instances in the order they were initialized. This is use for ordered cleanup of the components.
 	instances []componentInstance
 	registry  *registry.Registry
+	// inflight tracks IDs that are currently being initialized by some
+	// goroutine, so a concurrent Initialize call for the same ID can wait
+	// on the result instead of calling Init a second time.
+	inflight map[dependency.Instance]*initResult
+
+	observersMu sync.Mutex
+	observers   []LifecycleObserver
+
+	snapshotsMu sync.Mutex
+	snapshots   []namedSnapshot
 }
 
 func newTracker(registry *registry.Registry) *Tracker {
@@ -50,53 +78,151 @@ func newTracker(registry *registry.Registry) *Tracker {
 	}
 }
 
-// Initialize a test dependency and start tracking it.
+// Initialize a test dependency and start tracking it. All of the component's
+// transitive dependencies are resolved into an InitPlan first, so that
+// components with no dependency relationship between them can be
+// initialized concurrently instead of one at a time.
 func (t *Tracker) Initialize(ctx environment.ComponentContext, c component.Component) (interface{}, error) {
 	id := c.ID()
+	t.mu.Lock()
 	if s, ok := t.instanceMap[id]; ok {
 		// Already initialized.
+		t.mu.Unlock()
 		return s, nil
 	}
+	t.mu.Unlock()
 
-	// Make sure all dependencies of the component are initialized first.
-	depMap := make(map[dependency.Instance]interface{})
-	for _, depID := range c.Requires() {
-		depComp, ok := t.registry.Get(depID)
-		if !ok {
-			return nil, fmt.Errorf("unable to resolve dependency %s for component %s", depID, id)
-		}
+	plan, err := Plan(context.Background(), t.registry, []component.Component{c})
+	if err != nil {
+		return nil, err
+	}
 
-		// TODO(nmittler): We might want to protect against circular dependencies.
-		s, err := t.Initialize(ctx, depComp)
-		if err != nil {
+	for _, wave := range plan.Waves {
+		wave := wave
+		g := &errgroup.Group{}
+		for _, waveID := range wave {
+			waveID := waveID
+			done, res, won := t.claimInit(waveID)
+			if done {
+				// Already initialized by a previous call to Initialize.
+				continue
+			}
+			if !won {
+				// Some other goroutine - from an overlapping Initialize
+				// call, or from a DAG that shares this dependency - is
+				// already running Init for waveID.
+				// Wait for its result instead of running Init again.
+				g.Go(func() error {
+					<-res.done
+					return res.err
+				})
+				continue
+			}
+
+			g.Go(func() error {
+				return t.initComponent(ctx, waveID, plan, res)
+			})
+		}
+		if err := g.Wait(); err != nil {
 			return nil, err
 		}
+	}
+
+	t.mu.Lock()
+	s := t.instanceMap[id]
+	t.mu.Unlock()
+	return s, nil
+}
 
+// claimInit atomically decides what waveID's caller should do next:
+//   - done is true if waveID is already fully initialized; there is nothing
+//     left to do.
+//   - otherwise, won is true if the caller won the claim and must run
+//     Init itself, recording the outcome on the returned *initResult.
+//   - otherwise, won is false and res is the in-flight claim some other
+//     goroutine already holds; the caller must wait on res.done and use
+//     res.err instead of calling Init.
+func (t *Tracker) claimInit(id dependency.Instance) (done bool, res *initResult, won bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.instanceMap[id]; ok {
+		return true, nil, false
+	}
+	if existing, ok := t.inflight[id]; ok {
+		return false, existing, false
+	}
+
+	res = &initResult{done: make(chan struct{})}
+	if t.inflight == nil {
+		t.inflight = make(map[dependency.Instance]*initResult)
+	}
+	t.inflight[id] = res
+	return false, res, true
+}
+
+// initComponent initializes the single component identified by id, whose
+// dependencies must already be present in t.instanceMap because they were
+// initialized in an earlier wave of plan. The caller must have won the
+// initialization claim for id via claimInit; initComponent records its
+// outcome on res and releases the claim before returning, waking any
+// goroutines waiting on res.done.
+func (t *Tracker) initComponent(ctx environment.ComponentContext, id dependency.Instance, plan *InitPlan, res *initResult) (err error) {
+	defer func() {
+		res.err = err
+		t.mu.Lock()
+		delete(t.inflight, id)
+		t.mu.Unlock()
+		close(res.done)
+	}()
+
+	c, ok := plan.nodes[id]
+	if !ok {
+		return fmt.Errorf("unable to resolve component for dependency %s", id)
+	}
+
+	depMap := make(map[dependency.Instance]interface{})
+	for _, depID := range c.Requires() {
+		t.mu.Lock()
+		s, ok := t.instanceMap[depID]
+		t.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("dependency %s for component %s was not initialized by a prior wave", depID, id)
+		}
 		depMap[depID] = s
 	}
 
+	t.notifyInitStart(id)
+	start := time.Now()
 	s, err := c.Init(ctx, depMap)
+	t.notifyInitEnd(id, err, time.Since(start))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	t.mu.Lock()
 	t.instanceMap[id] = s
 	t.instances = append(t.instances, componentInstance{
 		id:    id,
 		value: s,
 	})
+	t.mu.Unlock()
 
-	return s, nil
+	return nil
 }
 
 // Get the tracked resource with the given ID.
 func (t *Tracker) Get(id dependency.Instance) (interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	s, ok := t.instanceMap[id]
 	return s, ok
 }
 
 // All returns all tracked resources.
 func (t *Tracker) All() []interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	all := make([]interface{}, len(t.instances))
 	for i, e := range t.instances {
 		all[i] = e.value
@@ -106,12 +232,17 @@ func (t *Tracker) All() []interface{} {
 
 // Reset the all Resettable resources.
 func (t *Tracker) Reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	var er error
 
 	for _, e := range t.instances {
 		if cl, ok := e.value.(Resettable); ok {
 			scopes.Framework.Debugf("Resetting state for dependency: %s", e.id)
-			if err := cl.Reset(); err != nil {
+			err := cl.Reset()
+			t.notifyResetEnd(e.id, err)
+			if err != nil {
 				scopes.Framework.Errorf("Error resetting dependency state: %s: %v", e.id, err)
 				er = multierr.Append(er, err)
 			}
@@ -123,10 +254,15 @@ func (t *Tracker) Reset() error {
 
 // Cleanup closes all resources that implement io.Closer
 func (t *Tracker) Cleanup() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	for _, e := range t.instances {
 		if cl, ok := e.value.(io.Closer); ok {
 			scopes.Framework.Debugf("Cleaning up state for dependency: %s", e.id)
-			if err := cl.Close(); err != nil {
+			err := cl.Close()
+			t.notifyCleanupEnd(e.id, err)
+			if err != nil {
 				scopes.Framework.Errorf("Error cleaning up dependency state: %s: %v", e.id, err)
 			}
 		}
@@ -0,0 +1,54 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package internal
+
+import (
+	"sync"
+	"testing"
+
+	"istio.io/istio/pkg/test/framework/dependency"
+)
+
+// TestTracker_Initialize_Concurrent exercises Initialize, Get, and All from
+// many goroutines at once, with `go test -race`, to guard against the
+// instanceMap/instances map-and-slice races that motivated promoting the
+// guarding mutex to a Tracker field.
+func TestTracker_Initialize_Concurrent(t *testing.T) {
+	shared := &fakeComponent{id: "shared"}
+	reg := newFakeRegistry(shared)
+	tracker := newTracker(reg)
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := tracker.Initialize(nil, shared); err != nil {
+				t.Errorf("Initialize() returned unexpected error: %v", err)
+			}
+			tracker.Get("shared")
+			tracker.All()
+		}()
+	}
+	wg.Wait()
+
+	if len(tracker.instances) != 1 {
+		t.Fatalf("expected the shared component to be initialized exactly once, got %d instances", len(tracker.instances))
+	}
+	if _, ok := tracker.Get(dependency.Instance("shared")); !ok {
+		t.Fatal("expected shared component to be tracked after Initialize")
+	}
+}